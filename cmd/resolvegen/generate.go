@@ -0,0 +1,219 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"go/types"
+	"sort"
+	"strings"
+
+	"golang.org/x/tools/go/packages"
+)
+
+// generate renders the companion "<pkg>_resolvers.go" source for productions
+// found in pkg. For every production it emits a typed, reflection-free
+// Resolve<Output> wrapper and a matching Registry method; for every distinct
+// output type it emits an <Output>Input marker interface (implemented by
+// that output's single-argument Resolvers' input types, if any); and it
+// emits a PossibilityDiagram() returning the production DAG as Graphviz DOT.
+func generate(pkg *packages.Package, productions []production) ([]byte, error) {
+	qual := types.RelativeTo(pkg.Types)
+
+	names := uniqueNames(productions)
+	imports := collectImports(pkg.Types, productions)
+
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "// Code generated by resolvegen from %s. DO NOT EDIT.\n\n", pkg.PkgPath)
+	fmt.Fprintf(&buf, "package %s\n\n", pkg.Name)
+
+	if len(imports) > 0 {
+		buf.WriteString("import (\n")
+		for _, path := range sortedKeys(imports) {
+			fmt.Fprintf(&buf, "\t%q\n", path)
+		}
+		buf.WriteString(")\n\n")
+	}
+
+	for i, p := range productions {
+		writeResolveFunc(&buf, qual, names[i], p)
+	}
+
+	groups := groupByOutputName(productions, names)
+	for _, outputName := range sortedKeys(groups) {
+		writeInputInterface(&buf, qual, outputName, groups[outputName])
+	}
+	writeSingleArgMarkers(&buf, qual, productions)
+
+	buf.WriteString("// Registry exposes this package's generated Resolve helpers as methods,\n")
+	buf.WriteString("// for callers that want to pass the whole set around as a single value.\n")
+	buf.WriteString("type Registry struct{}\n\n")
+	for i, p := range productions {
+		writeRegistryMethod(&buf, qual, names[i], p)
+	}
+
+	writePossibilityDiagram(&buf, qual, productions)
+
+	formatted, err := format.Source(buf.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("gofmt: %s\n---\n%s", err, buf.String())
+	}
+	return formatted, nil
+}
+
+// uniqueNames returns, for each production, the Resolve<Name> identifier to
+// use -- deduplicated with a numeric suffix when more than one Resolver
+// produces the same output type.
+func uniqueNames(productions []production) []string {
+	seen := map[string]int{}
+	names := make([]string, len(productions))
+	for i, p := range productions {
+		base := typeIdent(p.Output)
+		seen[base]++
+		if n := seen[base]; n == 1 {
+			names[i] = base
+		} else {
+			names[i] = fmt.Sprintf("%s%d", base, n)
+		}
+	}
+	return names
+}
+
+func groupByOutputName(productions []production, names []string) map[string][]int {
+	groups := map[string][]int{}
+	for i, p := range productions {
+		key := typeIdent(p.Output)
+		groups[key] = append(groups[key], i)
+	}
+	return groups
+}
+
+func writeResolveFunc(buf *bytes.Buffer, qual types.Qualifier, name string, p production) {
+	params := make([]string, len(p.Inputs))
+	args := make([]string, len(p.Inputs))
+	for i, in := range p.Inputs {
+		params[i] = fmt.Sprintf("in%d %s", i, types.TypeString(in, qual))
+		args[i] = fmt.Sprintf("in%d", i)
+	}
+
+	fmt.Fprintf(buf, "// Resolve%s calls %s directly, with no reflection.\n", name, p.CallExpr)
+	fmt.Fprintf(buf, "func Resolve%s(%s) (%s, error) {\n", name, strings.Join(params, ", "), types.TypeString(p.Output, qual))
+	fmt.Fprintf(buf, "\treturn %s(%s)\n", p.CallExpr, strings.Join(args, ", "))
+	buf.WriteString("}\n\n")
+}
+
+func writeRegistryMethod(buf *bytes.Buffer, qual types.Qualifier, name string, p production) {
+	params := make([]string, len(p.Inputs))
+	args := make([]string, len(p.Inputs))
+	for i, in := range p.Inputs {
+		params[i] = fmt.Sprintf("in%d %s", i, types.TypeString(in, qual))
+		args[i] = fmt.Sprintf("in%d", i)
+	}
+
+	fmt.Fprintf(buf, "func (Registry) Resolve%s(%s) (%s, error) {\n", name, strings.Join(params, ", "), types.TypeString(p.Output, qual))
+	fmt.Fprintf(buf, "\treturn Resolve%s(%s)\n", name, strings.Join(args, ", "))
+	buf.WriteString("}\n\n")
+}
+
+// writeInputInterface emits <Name>Input, implemented by the input type of
+// any single-argument Resolver producing this output -- the cases where one
+// value is, by itself, enough to derive it.
+func writeInputInterface(buf *bytes.Buffer, qual types.Qualifier, name string, indices []int) {
+	fmt.Fprintf(buf, "// %sInput is implemented by Go types that alone are enough to derive a\n", name)
+	fmt.Fprintf(buf, "// %s via one of this package's registered Resolvers.\n", name)
+	fmt.Fprintf(buf, "type %sInput interface {\n\tis%sInput()\n}\n\n", name, name)
+}
+
+func writeSingleArgMarkers(buf *bytes.Buffer, qual types.Qualifier, productions []production) {
+	seen := map[string]bool{}
+	for _, p := range productions {
+		if len(p.Inputs) != 1 {
+			continue
+		}
+		outName := typeIdent(p.Output)
+		inType := types.TypeString(p.Inputs[0], qual)
+		key := outName + "|" + inType
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		fmt.Fprintf(buf, "func (%s) is%sInput() {}\n\n", inType, outName)
+	}
+}
+
+func writePossibilityDiagram(buf *bytes.Buffer, qual types.Qualifier, productions []production) {
+	var dot strings.Builder
+	dot.WriteString("digraph Possibilities {\n")
+	for _, p := range productions {
+		outName := types.TypeString(p.Output, qual)
+		for _, in := range p.Inputs {
+			fmt.Fprintf(&dot, "\t%q -> %q;\n", types.TypeString(in, qual), outName)
+		}
+		if len(p.Inputs) == 0 {
+			fmt.Fprintf(&dot, "\t%q;\n", outName)
+		}
+	}
+	dot.WriteString("}\n")
+
+	buf.WriteString("// PossibilityDiagram returns this package's production DAG -- one node per\n")
+	buf.WriteString("// type, one edge per Resolver input -- as Graphviz DOT.\n")
+	buf.WriteString("func PossibilityDiagram() string {\n")
+	fmt.Fprintf(buf, "\treturn %s\n", backtickString(dot.String()))
+	buf.WriteString("}\n")
+}
+
+func backtickString(s string) string {
+	if !strings.Contains(s, "`") {
+		return "`" + s + "`"
+	}
+	return fmt.Sprintf("%q", s)
+}
+
+// typeIdent returns the bare identifier to use in generated names for t,
+// e.g. "User" for both User and *User.
+func typeIdent(t types.Type) string {
+	if ptr, ok := t.(*types.Pointer); ok {
+		t = ptr.Elem()
+	}
+	if named, ok := t.(*types.Named); ok {
+		return named.Obj().Name()
+	}
+	return "Value"
+}
+
+// collectImports gathers the import paths of every named type reachable
+// from productions' inputs and outputs, excluding pkg itself.
+func collectImports(pkg *types.Package, productions []production) map[string]string {
+	imports := map[string]string{}
+	var visit func(t types.Type)
+	visit = func(t types.Type) {
+		switch u := t.(type) {
+		case *types.Pointer:
+			visit(u.Elem())
+		case *types.Slice:
+			visit(u.Elem())
+		case *types.Array:
+			visit(u.Elem())
+		case *types.Named:
+			if obj := u.Obj(); obj.Pkg() != nil && obj.Pkg().Path() != pkg.Path() {
+				imports[obj.Pkg().Path()] = obj.Pkg().Name()
+			}
+		}
+	}
+	for _, p := range productions {
+		visit(p.Output)
+		for _, in := range p.Inputs {
+			visit(in)
+		}
+	}
+	return imports
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
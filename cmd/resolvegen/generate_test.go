@@ -0,0 +1,70 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	"golang.org/x/tools/go/packages"
+)
+
+func loadFixture(t *testing.T) *packages.Package {
+	t.Helper()
+	cfg := &packages.Config{
+		Mode: packages.NeedName | packages.NeedFiles | packages.NeedSyntax |
+			packages.NeedTypes | packages.NeedTypesInfo | packages.NeedImports,
+	}
+	pkgs, err := packages.Load(cfg, "./testdata/fixture")
+	if err != nil {
+		t.Fatal("Failed to load testdata/fixture:", err)
+	}
+	if packages.PrintErrors(pkgs) > 0 || len(pkgs) != 1 {
+		t.Fatal("testdata/fixture did not load cleanly")
+	}
+	return pkgs[0]
+}
+
+func TestFindProductions(t *testing.T) {
+	pkg := loadFixture(t)
+
+	productions := findProductions(pkg)
+	if len(productions) != 2 {
+		t.Fatalf("Expected 2 productions, got %d", len(productions))
+	}
+
+	byCall := map[string]production{}
+	for _, p := range productions {
+		byCall[p.CallExpr] = p
+	}
+
+	if _, ok := byCall["chiselStone"]; !ok {
+		t.Error("Expected a production for the NewResolver(chiselStone) registration")
+	}
+	if _, ok := byCall["pickupStick"]; !ok {
+		t.Error("Expected a production for the Productions.Add(Resolver{pickupStick}) registration")
+	}
+}
+
+func TestGenerate(t *testing.T) {
+	pkg := loadFixture(t)
+	productions := findProductions(pkg)
+
+	src, err := generate(pkg, productions)
+	if err != nil {
+		t.Fatal("generate failed:", err)
+	}
+	out := string(src)
+
+	for _, want := range []string{
+		"func ResolveStone(in0 Boulder) (Stone, error)",
+		"return chiselStone(in0)",
+		"func ResolveStick(in0 Tree) (Stick, error)",
+		"type StoneInput interface",
+		"func (Boulder) isStoneInput()",
+		"func PossibilityDiagram() string",
+		"Boulder\" -> \"Stone",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("Generated source missing %q:\n%s", want, out)
+		}
+	}
+}
@@ -0,0 +1,66 @@
+// Command resolvegen scans a package for resolve.NewResolver(fn) and
+// productions.Add(resolve.Resolver{fn}) calls and generates a companion
+// "<pkg>_resolvers.go" file with strongly-typed, reflection-free wrappers
+// around them -- see the doc of generate in generate.go for what gets emitted.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+
+	"golang.org/x/tools/go/packages"
+)
+
+func main() {
+	pkgPattern := flag.String("pkg", ".", "import path or pattern of the package to scan")
+	outName := flag.String("out", "", "generated file name (default: <package>_resolvers.go)")
+	flag.Parse()
+
+	if err := run(*pkgPattern, *outName); err != nil {
+		log.Fatal("resolvegen: ", err)
+	}
+}
+
+func run(pkgPattern, outName string) error {
+	cfg := &packages.Config{
+		Mode: packages.NeedName | packages.NeedFiles | packages.NeedSyntax |
+			packages.NeedTypes | packages.NeedTypesInfo | packages.NeedImports,
+	}
+	pkgs, err := packages.Load(cfg, pkgPattern)
+	if err != nil {
+		return fmt.Errorf("loading package %s: %s", pkgPattern, err)
+	}
+	if packages.PrintErrors(pkgs) > 0 {
+		return fmt.Errorf("package %s has errors", pkgPattern)
+	}
+	if len(pkgs) != 1 {
+		return fmt.Errorf("expected exactly one package for pattern %s, got %d", pkgPattern, len(pkgs))
+	}
+	pkg := pkgs[0]
+
+	productions := findProductions(pkg)
+	if len(productions) == 0 {
+		return fmt.Errorf("no resolve.NewResolver or ProductionMap.Add calls found in %s", pkg.PkgPath)
+	}
+
+	src, err := generate(pkg, productions)
+	if err != nil {
+		return fmt.Errorf("generating code: %s", err)
+	}
+
+	if outName == "" {
+		outName = pkg.Name + "_resolvers.go"
+	}
+	dir := filepath.Dir(pkg.GoFiles[0])
+	outPath := filepath.Join(dir, outName)
+
+	if err := os.WriteFile(outPath, src, 0644); err != nil {
+		return fmt.Errorf("writing %s: %s", outPath, err)
+	}
+
+	fmt.Fprintf(os.Stderr, "resolvegen: wrote %d resolver wrapper(s) to %s\n", len(productions), outPath)
+	return nil
+}
@@ -0,0 +1,171 @@
+package main
+
+import (
+	"bytes"
+	"go/ast"
+	"go/printer"
+	"go/types"
+
+	"golang.org/x/tools/go/packages"
+)
+
+// production describes one registered Resolver: the source expression that
+// evaluates to its ResolverFunc (so generated code can call it directly,
+// with no reflection) and the signature that drives codegen.
+type production struct {
+	CallExpr string
+	Inputs   []types.Type
+	Output   types.Type // the resolver's declared first return type, e.g. *User
+}
+
+// findProductions walks every file in pkg looking for calls of the shape
+// resolve.NewResolver(fn) or someProductionMap.Add(resolve.Resolver{fn}),
+// and returns one production per fn it can resolve to a named function,
+// method value, or other directly-callable expression.
+func findProductions(pkg *packages.Package) []production {
+	var productions []production
+
+	for _, file := range pkg.Syntax {
+		ast.Inspect(file, func(n ast.Node) bool {
+			call, ok := n.(*ast.CallExpr)
+			if !ok {
+				return true
+			}
+
+			if fn, ok := newResolverArg(pkg, call); ok {
+				if p, ok := buildProduction(pkg, fn); ok {
+					productions = append(productions, p)
+				}
+				return true
+			}
+
+			if fn, ok := productionMapAddArg(pkg, call); ok {
+				if p, ok := buildProduction(pkg, fn); ok {
+					productions = append(productions, p)
+				}
+			}
+
+			return true
+		})
+	}
+
+	return productions
+}
+
+// newResolverArg reports whether call is a call to resolve.NewResolver (by
+// any import alias) and, if so, returns its single argument.
+func newResolverArg(pkg *packages.Package, call *ast.CallExpr) (ast.Expr, bool) {
+	sel, ok := calleeName(call.Fun)
+	if !ok || sel != "NewResolver" || len(call.Args) != 1 {
+		return nil, false
+	}
+	if !calleeIsResolvePackage(pkg, call.Fun) {
+		return nil, false
+	}
+	return call.Args[0], true
+}
+
+// productionMapAddArg reports whether call is <x>.Add(resolve.Resolver{fn})
+// where <x> has type resolve.ProductionMap, and if so, returns fn.
+func productionMapAddArg(pkg *packages.Package, call *ast.CallExpr) (ast.Expr, bool) {
+	sel, ok := call.Fun.(*ast.SelectorExpr)
+	if !ok || sel.Sel.Name != "Add" || len(call.Args) != 1 {
+		return nil, false
+	}
+
+	recvType := pkg.TypesInfo.TypeOf(sel.X)
+	if recvType == nil || !isNamedType(recvType, "ProductionMap") {
+		return nil, false
+	}
+
+	lit, ok := call.Args[0].(*ast.CompositeLit)
+	if !ok {
+		return nil, false
+	}
+	litType := pkg.TypesInfo.TypeOf(lit)
+	if litType == nil || !isNamedType(litType, "Resolver") {
+		return nil, false
+	}
+
+	if len(lit.Elts) != 1 {
+		return nil, false
+	}
+	switch elt := lit.Elts[0].(type) {
+	case *ast.KeyValueExpr:
+		return elt.Value, true
+	default:
+		return elt, true
+	}
+}
+
+// calleeName returns the identifier a call expression's function resolves
+// to: "NewResolver" for both resolve.NewResolver(...) and a dot-imported
+// NewResolver(...).
+func calleeName(fun ast.Expr) (string, bool) {
+	switch f := fun.(type) {
+	case *ast.SelectorExpr:
+		return f.Sel.Name, true
+	case *ast.Ident:
+		return f.Name, true
+	}
+	return "", false
+}
+
+// calleeIsResolvePackage reports whether fun refers to an identifier
+// declared in the goresolve root package.
+func calleeIsResolvePackage(pkg *packages.Package, fun ast.Expr) bool {
+	var ident *ast.Ident
+	switch f := fun.(type) {
+	case *ast.SelectorExpr:
+		ident = f.Sel
+	case *ast.Ident:
+		ident = f
+	default:
+		return false
+	}
+
+	obj := pkg.TypesInfo.Uses[ident]
+	if obj == nil || obj.Pkg() == nil {
+		return false
+	}
+	return obj.Pkg().Name() == "resolve"
+}
+
+// isNamedType reports whether t is a named type called name, looking through
+// pointers.
+func isNamedType(t types.Type, name string) bool {
+	if ptr, ok := t.(*types.Pointer); ok {
+		t = ptr.Elem()
+	}
+	named, ok := t.(*types.Named)
+	return ok && named.Obj().Name() == name
+}
+
+// buildProduction derives a production from fnExpr, the expression passed as
+// a ResolverFunc. Anonymous function literals are skipped: there is no
+// package-level name a generated wrapper could call directly.
+func buildProduction(pkg *packages.Package, fnExpr ast.Expr) (production, bool) {
+	if _, ok := fnExpr.(*ast.FuncLit); ok {
+		return production{}, false
+	}
+
+	t := pkg.TypesInfo.TypeOf(fnExpr)
+	sig, ok := t.(*types.Signature)
+	if !ok || sig.Results().Len() != 2 {
+		return production{}, false
+	}
+
+	inputs := make([]types.Type, sig.Params().Len())
+	for i := range inputs {
+		inputs[i] = sig.Params().At(i).Type()
+	}
+
+	var buf bytes.Buffer
+	printer.Fprint(&buf, pkg.Fset, fnExpr)
+
+	return production{
+		CallExpr: buf.String(),
+		Inputs:   inputs,
+		Output:   sig.Results().At(0).Type(),
+	}, true
+}
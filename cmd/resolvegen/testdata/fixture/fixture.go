@@ -0,0 +1,29 @@
+// Package fixture is a small, self-contained set of Resolvers used by
+// resolvegen's own tests to exercise both registration styles it scans for.
+package fixture
+
+import "github.com/wyc/goresolve"
+
+type Boulder struct{ Weight int }
+type Stone struct{ Weight int }
+
+func chiselStone(b Boulder) (Stone, error) {
+	return Stone{Weight: b.Weight / 10}, nil
+}
+
+var namedResolver = resolve.NewResolver(chiselStone)
+
+type Tree struct{ Height int }
+type Stick struct{ Height int }
+
+func pickupStick(t Tree) (Stick, error) {
+	return Stick{Height: t.Height / 10}, nil
+}
+
+var Productions = resolve.ProductionMap{}
+
+func init() {
+	if err := Productions.Add(resolve.Resolver{pickupStick}); err != nil {
+		panic(err)
+	}
+}
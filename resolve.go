@@ -4,6 +4,8 @@ import (
 	"fmt"
 	"log"
 	"reflect"
+	"sort"
+	"strings"
 )
 
 /**
@@ -75,7 +77,7 @@ func (r Resolver) Resolve(inputs ...reflect.Value) (*reflect.Value, error) {
 	for i := 0; i < rType.NumIn(); i++ {
 		if rType.In(i) != inputs[i].Type() {
 			return nil, fmt.Errorf("Expected argument %d type to be %v, got %v",
-				rType.In(i), inputs[i].Type())
+				i, rType.In(i), inputs[i].Type())
 		}
 	}
 	outputValues := rVal.Call(inputs)
@@ -309,36 +311,208 @@ ResolverLoop:
 	}
 }
 
-/*
+// ResolveNode is one step of a concrete resolution plan: the Resolver chosen
+// to derive a value, along with the already-built nodes that supply whichever
+// of its inputs were not already on hand.
 type ResolveNode struct {
 	*Resolver
-	DependencyTuples [][]*ResolveNode
+	Dependencies []*ResolveNode
+}
+
+// execute runs the chain rooted at node, filling in args from memo where
+// possible and recursing into Dependencies otherwise. Every value it derives
+// is written back into memo, keyed by type, so that a value needed by more
+// than one node in the wider tree is only ever computed once.
+func (node *ResolveNode) execute(memo map[reflect.Type]reflect.Value) (reflect.Value, error) {
+	if v, ok := memo[node.OutputType()]; ok {
+		return v, nil
+	}
+
+	args := make([]reflect.Value, 0, len(node.InputTypes()))
+	for _, in := range node.InputTypes() {
+		if v, ok := memo[in]; ok {
+			args = append(args, v)
+			continue
+		}
+		var dep *ResolveNode
+		for _, d := range node.Dependencies {
+			if d.OutputType() == in {
+				dep = d
+				break
+			}
+		}
+		if dep == nil {
+			return reflect.Value{}, fmt.Errorf("no dependency built for input %v", in)
+		}
+
+		v, err := dep.execute(memo)
+		if err != nil {
+			return reflect.Value{}, err
+		}
+		memo[in] = v
+		args = append(args, v)
+	}
+
+	out, err := node.Resolver.Resolve(args...)
+	if err != nil {
+		return reflect.Value{}, err
+	}
+	memo[node.OutputType()] = *out
+	return *out, nil
+}
+
+// chainLength reports how many Resolver applications are needed to derive t
+// from have, using the existing PossibilityTree/PruneFor machinery to find
+// the shortest such chain. It returns a very large number if t is not
+// reachable at all.
+func (pm ProductionMap) chainLength(t reflect.Type, have []reflect.Type) int {
+	for _, h := range have {
+		if h == t {
+			return 0
+		}
+	}
+
+	root := pm.PossibilityTree(have...)
+	if err := root.PruneFor(t); err != nil {
+		return unreachable
+	}
+
+	type frame struct {
+		node  *PossibilityNode
+		depth int
+	}
+	queue := []frame{{root, 0}}
+	for len(queue) > 0 {
+		cur := queue[0]
+		queue = queue[1:]
+		if cur.node.Resolver != nil && cur.node.OutputType() == t {
+			return cur.depth
+		}
+		for _, next := range cur.node.NextSteps {
+			queue = append(queue, frame{next, cur.depth + 1})
+		}
+	}
+	return unreachable
+}
+
+// unreachable stands in for "infinitely far away" when ranking chains that
+// cannot actually derive their target.
+const unreachable = 1 << 30
+
+// candidateCost ranks how expensive it would be to use r to fill in a
+// missing type, given the types already in have: one step for r itself, plus
+// the shortest chain for each of its still-missing inputs.
+func (pm ProductionMap) candidateCost(r Resolver, have []reflect.Type) int {
+	cost := 1
+	for _, in := range r.MissingInputs(have) {
+		cost += pm.chainLength(in, have)
+	}
+	return cost
+}
+
+// buildResolveNode picks the shortest available chain that derives wanted
+// from have and builds a ResolveNode tree for it, recursing into whichever
+// resolver among pm[wanted] has the lowest candidateCost.
+func (pm ProductionMap) buildResolveNode(wanted reflect.Type, have []reflect.Type) (*ResolveNode, error) {
+	candidates := append([]Resolver{}, pm[wanted]...)
+	if len(candidates) == 0 {
+		return nil, fmt.Errorf("no Resolver produces %v", wanted)
+	}
+
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return pm.candidateCost(candidates[i], have) < pm.candidateCost(candidates[j], have)
+	})
+
+	return pm.buildChain(&candidates[0], have)
+}
+
+// buildChain builds the ResolveNode for r, recursively resolving whichever of
+// r's inputs are not already present in have.
+func (pm ProductionMap) buildChain(r *Resolver, have []reflect.Type) (*ResolveNode, error) {
+	missing := r.MissingInputs(have)
+	haveNext := append([]reflect.Type{}, have...)
+
+	deps := make([]*ResolveNode, 0, len(missing))
+	for _, m := range missing {
+		dep, err := pm.buildResolveNode(m, haveNext)
+		if err != nil {
+			return nil, fmt.Errorf("deriving %v (needed for %v): %s", m, r.OutputType(), err)
+		}
+		deps = append(deps, dep)
+		haveNext = append(haveNext, m)
+	}
+
+	return &ResolveNode{Resolver: r, Dependencies: deps}, nil
 }
 
-func (pm ProductionMap) BuildResolveTree(inputs ...reflect.Type) (root ResolveNode) {
+// multiError collects the failures of every resolution chain that was tried,
+// so that a caller can see why each one failed rather than just the last.
+type multiError []error
+
+func (m multiError) Error() string {
+	msgs := make([]string, len(m))
+	for i, err := range m {
+		msgs[i] = err.Error()
+	}
+	return fmt.Sprintf("all %d resolution chains failed: %s", len(m), strings.Join(msgs, "; "))
 }
 
+// Resolve derives a value of type wanted from the given inputs by chaining
+// together Resolvers registered in pm. It fails fast if wanted cannot
+// possibly be derived from inputs given the current productions, otherwise
+// it tries every Resolver that produces wanted, shortest chain first,
+// memoizing intermediate values so that a value needed in more than one
+// branch is only computed once. It returns the first chain to fully
+// succeed, or a multiError describing why each attempted chain failed.
 func (pm ProductionMap) Resolve(
-	wantedType reflect.Type,
+	wanted reflect.Type,
 	inputs ...reflect.Value,
-) (
-	output *reflect.Value,
-	err error,
-) {
-	for _, input := range inputs {
-		for _, resolver := range pm[input.Type()] {
-			params, err := resolver.FitInputs(inputs...)
-			if err != nil {
-				return resolver.Resolve(params...)
-			} else {
-				pm.Resolve(input.Type())
-			}
+) (output reflect.Value, err error) {
+	have := make([]reflect.Type, len(inputs))
+	baseMemo := make(map[reflect.Type]reflect.Value, len(inputs))
+	for i, v := range inputs {
+		have[i] = v.Type()
+		baseMemo[v.Type()] = v
+	}
+
+	if v, ok := baseMemo[wanted]; ok {
+		return v, nil
+	}
+
+	root := pm.PossibilityTree(have...)
+	if pruneErr := root.PruneFor(wanted); pruneErr != nil {
+		return reflect.Value{}, fmt.Errorf("%v cannot be derived from the given inputs: %s", wanted, pruneErr)
+	}
+
+	candidates := append([]Resolver{}, pm[wanted]...)
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return pm.candidateCost(candidates[i], have) < pm.candidateCost(candidates[j], have)
+	})
+
+	var errs []error
+	for i := range candidates {
+		node, buildErr := pm.buildChain(&candidates[i], have)
+		if buildErr != nil {
+			errs = append(errs, buildErr)
+			continue
 		}
+
+		memo := make(map[reflect.Type]reflect.Value, len(baseMemo))
+		for t, v := range baseMemo {
+			memo[t] = v
+		}
+
+		out, execErr := node.execute(memo)
+		if execErr != nil {
+			errs = append(errs, execErr)
+			continue
+		}
+		return out, nil
 	}
-	return nil, fmt.Errorf("Could not derive requested type")
+
+	return reflect.Value{}, multiError(errs)
 }
 
-*/
 /*
 var Productions = ProductionMap{}
 
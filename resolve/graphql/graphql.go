@@ -0,0 +1,328 @@
+// Package graphql exposes a resolve.ProductionMap as a queryable GraphQL
+// schema, following the resolver-per-field style of graphql-go/graphql:
+// each Resolver's output type becomes an Object type whose fields mirror its
+// Go struct fields, and becomes a top-level Query field whose arguments are
+// that Resolver's own InputTypes(). Query-field resolution dispatches
+// through ProductionMap.Resolve with the values of those arguments. Because
+// a field's arguments are only its Resolver's own inputs, a type built in
+// several steps cannot be queried from the root inputs alone: the query
+// must supply the inputs of the last step (e.g. axe takes stick and stone,
+// not boulder and tree).
+package graphql
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"reflect"
+	"strings"
+
+	gql "github.com/graphql-go/graphql"
+
+	"github.com/wyc/goresolve"
+)
+
+// builder accumulates the Object/InputObject types generated while walking a
+// ProductionMap, so that a struct type reached from two different paths is
+// only translated once.
+type builder struct {
+	pm      resolve.ProductionMap
+	objects map[reflect.Type]*gql.Object
+	inputs  map[reflect.Type]*gql.InputObject
+}
+
+// Schema builds a GraphQL schema exposing every type in pm that is reachable
+// from roots -- as determined by pm.PossibilityTree(roots...) -- as a
+// top-level Query field.
+func Schema(pm resolve.ProductionMap, roots ...reflect.Type) (gql.Schema, error) {
+	b := &builder{
+		pm:      pm,
+		objects: map[reflect.Type]*gql.Object{},
+		inputs:  map[reflect.Type]*gql.InputObject{},
+	}
+
+	reachable := reachableTypes(pm, roots)
+
+	queryFields := gql.Fields{}
+	for _, r := range pm.List() {
+		outputType := r.OutputType()
+		if !reachable[outputType] {
+			continue
+		}
+
+		field, err := b.queryField(*r)
+		if err != nil {
+			return gql.Schema{}, fmt.Errorf("building query field for %v: %s", outputType, err)
+		}
+		queryFields[fieldName(outputType)] = field
+	}
+
+	query := gql.NewObject(gql.ObjectConfig{Name: "Query", Fields: queryFields})
+	return gql.NewSchema(gql.SchemaConfig{Query: query})
+}
+
+// reachableTypes returns the set of output types, including the roots
+// themselves, that pm.PossibilityTree can reach from roots.
+func reachableTypes(pm resolve.ProductionMap, roots []reflect.Type) map[reflect.Type]bool {
+	reachable := map[reflect.Type]bool{}
+	for _, t := range roots {
+		reachable[t] = true
+	}
+
+	var walk func(n *resolve.PossibilityNode)
+	walk = func(n *resolve.PossibilityNode) {
+		if n.Resolver != nil {
+			reachable[n.OutputType()] = true
+		}
+		for _, next := range n.NextSteps {
+			walk(next)
+		}
+	}
+	walk(pm.PossibilityTree(roots...))
+
+	return reachable
+}
+
+// queryField builds the top-level Query field for r: its Type is r's output
+// type as a GraphQL Object, its Args are r's InputTypes(), and it dispatches
+// through pm.Resolve with the values given for those Args.
+func (b *builder) queryField(r resolve.Resolver) (*gql.Field, error) {
+	args := gql.FieldConfigArgument{}
+	inputTypes := r.InputTypes()
+	for _, in := range inputTypes {
+		argType, err := b.inputFor(in)
+		if err != nil {
+			return nil, err
+		}
+		args[fieldName(in)] = &gql.ArgumentConfig{Type: argType}
+	}
+
+	outputType := r.OutputType()
+	pm := b.pm
+	return &gql.Field{
+		Type: b.objectFor(outputType),
+		Args: args,
+		Resolve: func(p gql.ResolveParams) (interface{}, error) {
+			values := make([]reflect.Value, len(inputTypes))
+			for i, in := range inputTypes {
+				v, err := valueFromArg(in, p.Args[fieldName(in)])
+				if err != nil {
+					return nil, err
+				}
+				values[i] = v
+			}
+			out, err := pm.Resolve(outputType, values...)
+			if err != nil {
+				return nil, err
+			}
+			return out.Interface(), nil
+		},
+	}, nil
+}
+
+// objectFor returns the GraphQL Object type for t, building it (and
+// recursively, the Objects for any struct-typed fields) the first time t is
+// seen.
+func (b *builder) objectFor(t reflect.Type) *gql.Object {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if obj, ok := b.objects[t]; ok {
+		return obj
+	}
+
+	fields := gql.Fields{}
+	obj := gql.NewObject(gql.ObjectConfig{Name: t.Name(), Fields: fields})
+	b.objects[t] = obj // register before recursing, in case of self-reference
+
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if f.PkgPath != "" {
+			continue // unexported; reflect can't read it, so don't expose it
+		}
+		fields[fieldName2(f)] = &gql.Field{
+			Type:    b.outputFor(f.Type),
+			Resolve: structFieldResolver(f),
+		}
+	}
+
+	return obj
+}
+
+// outputFor maps a Go type to a GraphQL output type: a scalar for
+// primitives, a List for slices, and a generated Object for structs.
+func (b *builder) outputFor(t reflect.Type) gql.Output {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if scalar, ok := scalarFor(t); ok {
+		return scalar
+	}
+	if t.Kind() == reflect.Slice {
+		return gql.NewList(b.outputFor(t.Elem()))
+	}
+	if t.Kind() == reflect.Struct {
+		return b.objectFor(t)
+	}
+	return gql.String
+}
+
+// inputFor maps a Go type to a GraphQL input type: a scalar for primitives,
+// or a generated InputObject for structs.
+func (b *builder) inputFor(t reflect.Type) (gql.Input, error) {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if scalar, ok := scalarFor(t); ok {
+		return scalar, nil
+	}
+	if t.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("cannot map %v to a GraphQL input type", t)
+	}
+
+	if input, ok := b.inputs[t]; ok {
+		return input, nil
+	}
+
+	fields := gql.InputObjectConfigFieldMap{}
+	input := gql.NewInputObject(gql.InputObjectConfig{Name: t.Name() + "Input", Fields: fields})
+	b.inputs[t] = input
+
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if f.PkgPath != "" {
+			continue // unexported; reflect can't read it, so don't expose it
+		}
+		inType, err := b.inputFor(f.Type)
+		if err != nil {
+			return nil, fmt.Errorf("field %s of %v: %s", f.Name, t, err)
+		}
+		fields[fieldName2(f)] = &gql.InputObjectFieldConfig{Type: inType}
+	}
+
+	return input, nil
+}
+
+// scalarFor maps a Go primitive kind to the corresponding GraphQL scalar.
+func scalarFor(t reflect.Type) (*gql.Scalar, bool) {
+	switch t.Kind() {
+	case reflect.String:
+		return gql.String, true
+	case reflect.Bool:
+		return gql.Boolean, true
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return gql.Int, true
+	case reflect.Float32, reflect.Float64:
+		return gql.Float, true
+	}
+	return nil, false
+}
+
+// valueFromArg converts a decoded GraphQL argument (map[string]interface{}
+// for input objects, a Go primitive for scalars) into a reflect.Value of
+// type t.
+func valueFromArg(t reflect.Type, arg interface{}) (reflect.Value, error) {
+	if arg == nil {
+		return reflect.Value{}, fmt.Errorf("missing required argument of type %v", t)
+	}
+
+	if t.Kind() == reflect.Struct {
+		asMap, ok := arg.(map[string]interface{})
+		if !ok {
+			return reflect.Value{}, fmt.Errorf("expected input object for %v, got %T", t, arg)
+		}
+		out := reflect.New(t).Elem()
+		for i := 0; i < t.NumField(); i++ {
+			f := t.Field(i)
+			raw, ok := asMap[fieldName2(f)]
+			if !ok {
+				continue
+			}
+			v, err := valueFromArg(f.Type, raw)
+			if err != nil {
+				return reflect.Value{}, err
+			}
+			out.Field(i).Set(v)
+		}
+		return out, nil
+	}
+
+	v := reflect.ValueOf(arg)
+	if !v.Type().ConvertibleTo(t) {
+		return reflect.Value{}, fmt.Errorf("cannot use %v as %v", v.Type(), t)
+	}
+	return v.Convert(t), nil
+}
+
+func structFieldResolver(f reflect.StructField) gql.FieldResolveFn {
+	return func(p gql.ResolveParams) (interface{}, error) {
+		src := reflect.ValueOf(p.Source)
+		for src.Kind() == reflect.Ptr {
+			src = src.Elem()
+		}
+		return src.FieldByName(f.Name).Interface(), nil
+	}
+}
+
+// fieldName derives a GraphQL field/argument name from a Go type: its name
+// with the first letter lower-cased.
+func fieldName(t reflect.Type) string {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	return lowerFirst(t.Name())
+}
+
+// fieldName2 derives a GraphQL field name for a struct field, honoring a
+// `graphql:"name"` tag when present.
+func fieldName2(f reflect.StructField) string {
+	if tag := f.Tag.Get("graphql"); tag != "" {
+		return tag
+	}
+	return lowerFirst(f.Name)
+}
+
+func lowerFirst(s string) string {
+	if s == "" {
+		return s
+	}
+	return strings.ToLower(s[:1]) + s[1:]
+}
+
+// request is the shape of a single GraphQL-over-HTTP POST body.
+type request struct {
+	Query         string                 `json:"query"`
+	OperationName string                 `json:"operationName"`
+	Variables     map[string]interface{} `json:"variables"`
+}
+
+// NewHandler returns an http.Handler that accepts POST'd GraphQL queries
+// against schema and returns JSON-encoded results.
+func NewHandler(schema gql.Schema) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			http.Error(w, "only POST is supported", http.StatusMethodNotAllowed)
+			return
+		}
+
+		var req request
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, fmt.Sprintf("invalid request body: %s", err), http.StatusBadRequest)
+			return
+		}
+
+		result := gql.Do(gql.Params{
+			Schema:         schema,
+			RequestString:  req.Query,
+			VariableValues: req.Variables,
+			OperationName:  req.OperationName,
+			Context:        r.Context(),
+		})
+
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(result); err != nil {
+			http.Error(w, fmt.Sprintf("failed to encode response: %s", err), http.StatusInternalServerError)
+		}
+	})
+}
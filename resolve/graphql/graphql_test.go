@@ -0,0 +1,201 @@
+package graphql
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	gql "github.com/graphql-go/graphql"
+	"github.com/wyc/goresolve"
+)
+
+type Boulder struct{ Weight int }
+type Tree struct{ Height int }
+type Stick struct{ Height int }
+type Stone struct{ Weight int }
+type Axe struct{ Weight, Height int }
+
+func buildAxeProductions(t *testing.T) resolve.ProductionMap {
+	productions := resolve.ProductionMap{}
+
+	chiselStone := func(b Boulder) (Stone, error) { return Stone{Weight: b.Weight / 10}, nil }
+	pickupStick := func(tr Tree) (Stick, error) { return Stick{Height: tr.Height / 10}, nil }
+	assembleAxe := func(stick Stick, stone Stone) (Axe, error) {
+		return Axe{Height: stick.Height, Weight: stone.Weight}, nil
+	}
+
+	for _, r := range []interface{}{chiselStone, pickupStick, assembleAxe} {
+		if err := productions.Add(resolve.Resolver{ResolverFunc: r}); err != nil {
+			t.Fatal("Failed to add Resolver:", err)
+		}
+	}
+	return productions
+}
+
+func TestSchemaResolvesQueryFromResolverInputs(t *testing.T) {
+	productions := buildAxeProductions(t)
+
+	schema, err := Schema(productions, reflect.TypeOf(Boulder{}), reflect.TypeOf(Tree{}))
+	if err != nil {
+		t.Fatal("Failed to build schema:", err)
+	}
+
+	handler := NewHandler(schema)
+
+	body, _ := json.Marshal(map[string]interface{}{
+		"query": `{ axe(stick: {height: 5}, stone: {weight: 10}) { weight height } }`,
+	})
+	req := httptest.NewRequest(http.MethodPost, "/graphql", bytes.NewReader(body))
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+
+	var resp struct {
+		Data struct {
+			Axe struct {
+				Weight int `json:"weight"`
+				Height int `json:"height"`
+			} `json:"axe"`
+		} `json:"data"`
+		Errors []struct {
+			Message string `json:"message"`
+		} `json:"errors"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatal("Failed to decode response:", err, w.Body.String())
+	}
+	if len(resp.Errors) > 0 {
+		t.Fatalf("Unexpected GraphQL errors: %+v", resp.Errors)
+	}
+	if resp.Data.Axe.Weight != 10 || resp.Data.Axe.Height != 5 {
+		t.Errorf("Expected Axe{Weight: 10, Height: 5}, got %+v", resp.Data.Axe)
+	}
+}
+
+type Gem struct {
+	Karat int `graphql:"carats"`
+}
+
+type Ring struct {
+	Gem     Gem
+	private int
+}
+
+func TestSchemaSkipsUnexportedFields(t *testing.T) {
+	productions := resolve.ProductionMap{}
+
+	setGem := func(b Boulder) (Ring, error) { return Ring{Gem: Gem{Karat: b.Weight / 100}, private: 1}, nil }
+	if err := productions.Add(resolve.Resolver{ResolverFunc: setGem}); err != nil {
+		t.Fatal("Failed to add Resolver:", err)
+	}
+
+	schema, err := Schema(productions, reflect.TypeOf(Boulder{}))
+	if err != nil {
+		t.Fatal("Failed to build schema:", err)
+	}
+
+	ringType, ok := schema.TypeMap()["Ring"].(*gql.Object)
+	if !ok {
+		t.Fatal("Expected a Ring object type in the schema")
+	}
+	if _, ok := ringType.Fields()["private"]; ok {
+		t.Error("Expected unexported field private to be absent from the Ring object")
+	}
+	if _, ok := ringType.Fields()["gem"]; !ok {
+		t.Error("Expected exported field gem to be present on the Ring object")
+	}
+
+	handler := NewHandler(schema)
+	body, _ := json.Marshal(map[string]interface{}{
+		"query": `{ ring(boulder: {weight: 500}) { gem { carats } } }`,
+	})
+	req := httptest.NewRequest(http.MethodPost, "/graphql", bytes.NewReader(body))
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, req)
+
+	var resp struct {
+		Data struct {
+			Ring struct {
+				Gem struct {
+					Carats int `json:"carats"`
+				} `json:"gem"`
+			} `json:"ring"`
+		} `json:"data"`
+		Errors []struct {
+			Message string `json:"message"`
+		} `json:"errors"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatal("Failed to decode response:", err, w.Body.String())
+	}
+	if len(resp.Errors) > 0 {
+		t.Fatalf("Unexpected GraphQL errors querying only exported fields: %+v", resp.Errors)
+	}
+	if resp.Data.Ring.Gem.Carats != 5 {
+		t.Errorf("Expected Ring.Gem{Karat: 5}, got %+v", resp.Data.Ring.Gem)
+	}
+}
+
+func TestSchemaHonorsGraphQLNameTag(t *testing.T) {
+	productions := resolve.ProductionMap{}
+
+	cutGem := func(b Boulder) (Gem, error) { return Gem{Karat: b.Weight / 100}, nil }
+	if err := productions.Add(resolve.Resolver{ResolverFunc: cutGem}); err != nil {
+		t.Fatal("Failed to add Resolver:", err)
+	}
+
+	schema, err := Schema(productions, reflect.TypeOf(Boulder{}))
+	if err != nil {
+		t.Fatal("Failed to build schema:", err)
+	}
+	handler := NewHandler(schema)
+
+	body, _ := json.Marshal(map[string]interface{}{
+		"query": `{ gem(boulder: {weight: 500}) { carats } }`,
+	})
+	req := httptest.NewRequest(http.MethodPost, "/graphql", bytes.NewReader(body))
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, req)
+
+	var resp struct {
+		Data struct {
+			Gem struct {
+				Carats int `json:"carats"`
+			} `json:"gem"`
+		} `json:"data"`
+		Errors []struct {
+			Message string `json:"message"`
+		} `json:"errors"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatal("Failed to decode response:", err, w.Body.String())
+	}
+	if len(resp.Errors) > 0 {
+		t.Fatalf("Unexpected GraphQL errors: %+v", resp.Errors)
+	}
+	if resp.Data.Gem.Carats != 5 {
+		t.Errorf("Expected Gem{Karat: 5} exposed as field %q, got %+v", "carats", resp.Data.Gem)
+	}
+}
+
+func TestSchemaOnlyExposesReachableTypes(t *testing.T) {
+	productions := buildAxeProductions(t)
+
+	schema, err := Schema(productions, reflect.TypeOf(Boulder{}))
+	if err != nil {
+		t.Fatal("Failed to build schema:", err)
+	}
+
+	if _, ok := schema.QueryType().Fields()["stick"]; ok {
+		t.Error("Expected stick field to be absent when Tree is not a declared root")
+	}
+	if _, ok := schema.QueryType().Fields()["stone"]; !ok {
+		t.Error("Expected stone field to be present when Boulder is a declared root")
+	}
+}
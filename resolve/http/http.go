@@ -0,0 +1,202 @@
+// Package http adapts resolve.ProductionMap to HTTP handlers: it lets a
+// handler declare the identity and role types it needs as ordinary
+// arguments, and derives each of them from the incoming *http.Request before
+// the handler runs.
+//
+//	AddIdentityResolverHTTP(User{}, RequestToUser)
+//	AddRoleResolver(User.GetManager)
+//	http.Handle("/manager", AuthedHTTP(ManagerInfo))
+package http
+
+import (
+	"fmt"
+	"net/http"
+	"reflect"
+
+	"github.com/wyc/goresolve"
+)
+
+var (
+	errorType          = reflect.TypeOf((*error)(nil)).Elem()
+	responseWriterType = reflect.TypeOf((*http.ResponseWriter)(nil)).Elem()
+	requestType        = reflect.TypeOf((*http.Request)(nil))
+)
+
+// IdentityResolver derives the root identity value for a request (e.g. a
+// User). It is the entry point into Roles for AuthedHTTP: if it fails, the
+// request is rejected with 401 before any role resolver runs.
+type IdentityResolver struct {
+	Type    reflect.Type
+	Resolve func(w http.ResponseWriter, r *http.Request) (reflect.Value, error)
+}
+
+// Registry holds the identity resolver and the ProductionMap of role
+// resolvers that AuthedHTTP uses to fill in a handler's arguments.
+type Registry struct {
+	Identity IdentityResolver
+	Roles    resolve.ProductionMap
+}
+
+// NewRegistry returns an empty Registry, ready to have identity and role
+// resolvers added to it.
+func NewRegistry() *Registry {
+	return &Registry{Roles: resolve.ProductionMap{}}
+}
+
+// AddIdentityResolverHTTP registers f, a func(http.ResponseWriter,
+// *http.Request) (*T, error), as the Registry's identity resolver. zero is a
+// T{} used only to capture T's reflect.Type.
+func (reg *Registry) AddIdentityResolverHTTP(zero interface{}, f interface{}) error {
+	fType := reflect.TypeOf(f)
+	if fType.Kind() != reflect.Func || fType.NumIn() != 2 || fType.NumOut() != 2 {
+		return fmt.Errorf("AddIdentityResolverHTTP: f must be func(http.ResponseWriter, *http.Request) (*T, error)")
+	}
+	if fType.In(0) != responseWriterType || fType.In(1) != requestType {
+		return fmt.Errorf("AddIdentityResolverHTTP: f's arguments must be (http.ResponseWriter, *http.Request)")
+	}
+	if fType.Out(0).Kind() != reflect.Ptr || fType.Out(0).Elem() != reflect.TypeOf(zero) {
+		return fmt.Errorf("AddIdentityResolverHTTP: f must return (*%v, error)", reflect.TypeOf(zero))
+	}
+	if !fType.Out(1).Implements(errorType) {
+		return fmt.Errorf("AddIdentityResolverHTTP: f's second output does not implement error")
+	}
+
+	fVal := reflect.ValueOf(f)
+	identityType := reflect.TypeOf(zero)
+	reg.Identity = IdentityResolver{
+		Type: identityType,
+		Resolve: func(w http.ResponseWriter, r *http.Request) (reflect.Value, error) {
+			out := fVal.Call([]reflect.Value{reflect.ValueOf(w), reflect.ValueOf(r)})
+			if !out[1].IsNil() {
+				err, _ := out[1].Interface().(error)
+				return reflect.Value{}, err
+			}
+			if out[0].IsNil() {
+				return reflect.Value{}, fmt.Errorf("identity resolver returned nil %v", identityType)
+			}
+			return out[0].Elem(), nil
+		},
+	}
+	return nil
+}
+
+// AddRoleResolver registers f, a method value such as User.GetManager (i.e.
+// func(T) (*V, error)), as a role resolver: given an identity of type T it
+// derives a V. The pointer result is unwrapped so that handlers can declare
+// their role argument as a plain V, matching how Resolver outputs already
+// work elsewhere in this package.
+func (reg *Registry) AddRoleResolver(f interface{}) error {
+	fVal := reflect.ValueOf(f)
+	fType := fVal.Type()
+	if fType.Kind() != reflect.Func || fType.NumIn() != 1 || fType.NumOut() != 2 {
+		return fmt.Errorf("AddRoleResolver: expected func(T) (*V, error), got %v", fType)
+	}
+	if !fType.Out(1).Implements(errorType) {
+		return fmt.Errorf("AddRoleResolver: second output does not implement error")
+	}
+
+	outType := fType.Out(0)
+	if outType.Kind() != reflect.Ptr {
+		return reg.Roles.Add(resolve.Resolver{ResolverFunc: f})
+	}
+
+	inType := fType.In(0)
+	valueType := outType.Elem()
+	wrapped := reflect.MakeFunc(
+		reflect.FuncOf([]reflect.Type{inType}, []reflect.Type{valueType, errorType}, false),
+		func(args []reflect.Value) []reflect.Value {
+			out := fVal.Call(args)
+			ptr, errOut := out[0], out[1]
+			if !errOut.IsNil() {
+				return []reflect.Value{reflect.Zero(valueType), errOut}
+			}
+			if ptr.IsNil() {
+				err := reflect.ValueOf(fmt.Errorf("%v resolver returned nil %v", inType, valueType))
+				return []reflect.Value{reflect.Zero(valueType), err}
+			}
+			return []reflect.Value{ptr.Elem(), reflect.Zero(errorType)}
+		},
+	).Interface()
+
+	return reg.Roles.Add(resolve.Resolver{ResolverFunc: wrapped})
+}
+
+// AuthedHTTP wraps handler -- a func(http.ResponseWriter, *http.Request, T1,
+// T2, ...) error -- into an http.Handler that derives each Ti from the
+// request using reg's identity resolver and role ProductionMap. An identity
+// resolution failure is reported as 401, a role resolution failure as 403,
+// and a handler error or panic as 500.
+func (reg *Registry) AuthedHTTP(handler interface{}) http.Handler {
+	hType := reflect.TypeOf(handler)
+	hVal := reflect.ValueOf(handler)
+
+	if hType.Kind() != reflect.Func || hType.NumIn() < 2 ||
+		hType.In(0) != responseWriterType || hType.In(1) != requestType {
+		panic("AuthedHTTP: handler must be func(http.ResponseWriter, *http.Request, ...) error")
+	}
+	if hType.NumOut() != 1 || !hType.Out(0).Implements(errorType) {
+		panic("AuthedHTTP: handler must return error")
+	}
+
+	wanted := make([]reflect.Type, hType.NumIn()-2)
+	for i := range wanted {
+		wanted[i] = hType.In(i + 2)
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		defer func() {
+			if rec := recover(); rec != nil {
+				http.Error(w, fmt.Sprintf("internal error: %v", rec), http.StatusInternalServerError)
+			}
+		}()
+
+		if reg.Identity.Resolve == nil {
+			http.Error(w, "internal error: no identity resolver registered", http.StatusInternalServerError)
+			return
+		}
+
+		identity, err := reg.Identity.Resolve(w, r)
+		if err != nil {
+			http.Error(w, "unauthorized: "+err.Error(), http.StatusUnauthorized)
+			return
+		}
+
+		args := make([]reflect.Value, hType.NumIn())
+		args[0] = reflect.ValueOf(w)
+		args[1] = reflect.ValueOf(r)
+		for i, t := range wanted {
+			if t == identity.Type() {
+				args[i+2] = identity
+				continue
+			}
+			v, err := reg.Roles.Resolve(t, identity)
+			if err != nil {
+				http.Error(w, "forbidden: "+err.Error(), http.StatusForbidden)
+				return
+			}
+			args[i+2] = v
+		}
+
+		out := hVal.Call(args)
+		if !out[0].IsNil() {
+			err, _ := out[0].Interface().(error)
+			http.Error(w, "internal error: "+err.Error(), http.StatusInternalServerError)
+		}
+	})
+}
+
+// defaultRegistry backs the package-level AddIdentityResolverHTTP,
+// AddRoleResolver and AuthedHTTP helpers.
+var defaultRegistry = NewRegistry()
+
+func AddIdentityResolverHTTP(zero interface{}, f interface{}) error {
+	return defaultRegistry.AddIdentityResolverHTTP(zero, f)
+}
+
+func AddRoleResolver(f interface{}) error {
+	return defaultRegistry.AddRoleResolver(f)
+}
+
+func AuthedHTTP(handler interface{}) http.Handler {
+	return defaultRegistry.AuthedHTTP(handler)
+}
@@ -0,0 +1,127 @@
+package http
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+type Manager struct {
+	CanHire bool
+	CanFire bool
+}
+
+type Employee struct {
+	Title  string
+	Salary float64
+}
+
+type User struct {
+	Email string
+}
+
+func RequestToUser(w http.ResponseWriter, r *http.Request) (*User, error) {
+	if r.Header.Get("Authorization") == "" {
+		return nil, fmt.Errorf("missing Authorization header")
+	}
+	return &User{Email: "person@company.com"}, nil
+}
+
+func (u User) GetManager() (*Manager, error) {
+	return &Manager{CanHire: true, CanFire: false}, nil
+}
+
+func (u User) GetEmployee() (*Employee, error) {
+	return nil, fmt.Errorf("not an employee")
+}
+
+func ManagerInfo(w http.ResponseWriter, r *http.Request, m Manager) (err error) {
+	_, err = w.Write([]byte(fmt.Sprintf("Can Hire: %v\nCan Fire: %v", m.CanHire, m.CanFire)))
+	return err
+}
+
+func EmployeeInfo(w http.ResponseWriter, r *http.Request, e Employee) (err error) {
+	_, err = w.Write([]byte(fmt.Sprintf("Title: %v\nSalary: $%0.2f", e.Title, e.Salary)))
+	return err
+}
+
+func UserInfo(w http.ResponseWriter, r *http.Request, u User) (err error) {
+	_, err = w.Write([]byte(fmt.Sprintf("Email: %v", u.Email)))
+	return err
+}
+
+func newTestRegistry(t *testing.T) *Registry {
+	reg := NewRegistry()
+	if err := reg.AddIdentityResolverHTTP(User{}, RequestToUser); err != nil {
+		t.Fatal("Failed to register identity resolver:", err)
+	}
+	if err := reg.AddRoleResolver(User.GetManager); err != nil {
+		t.Fatal("Failed to register GetManager role resolver:", err)
+	}
+	if err := reg.AddRoleResolver(User.GetEmployee); err != nil {
+		t.Fatal("Failed to register GetEmployee role resolver:", err)
+	}
+	return reg
+}
+
+func TestAuthedHTTPManagerInfo(t *testing.T) {
+	reg := newTestRegistry(t)
+
+	req := httptest.NewRequest("GET", "/manager", nil)
+	req.Header.Set("Authorization", "Bearer token")
+	w := httptest.NewRecorder()
+
+	reg.AuthedHTTP(ManagerInfo).ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	if w.Body.String() != "Can Hire: true\nCan Fire: false" {
+		t.Errorf("Unexpected body: %q", w.Body.String())
+	}
+}
+
+func TestAuthedHTTPUserInfo(t *testing.T) {
+	reg := newTestRegistry(t)
+
+	req := httptest.NewRequest("GET", "/user", nil)
+	req.Header.Set("Authorization", "Bearer token")
+	w := httptest.NewRecorder()
+
+	reg.AuthedHTTP(UserInfo).ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	if w.Body.String() != "Email: person@company.com" {
+		t.Errorf("Unexpected body: %q", w.Body.String())
+	}
+}
+
+func TestAuthedHTTPIdentityFailureReturns401(t *testing.T) {
+	reg := newTestRegistry(t)
+
+	req := httptest.NewRequest("GET", "/manager", nil)
+	w := httptest.NewRecorder()
+
+	reg.AuthedHTTP(ManagerInfo).ServeHTTP(w, req)
+
+	if w.Code != http.StatusUnauthorized {
+		t.Fatalf("Expected 401, got %d", w.Code)
+	}
+}
+
+func TestAuthedHTTPRoleFailureReturns403(t *testing.T) {
+	reg := newTestRegistry(t)
+
+	req := httptest.NewRequest("GET", "/employee", nil)
+	req.Header.Set("Authorization", "Bearer token")
+	w := httptest.NewRecorder()
+
+	reg.AuthedHTTP(EmployeeInfo).ServeHTTP(w, req)
+
+	if w.Code != http.StatusForbidden {
+		t.Fatalf("Expected 403, got %d", w.Code)
+	}
+}
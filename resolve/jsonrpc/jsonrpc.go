@@ -0,0 +1,266 @@
+// Package jsonrpc publishes a resolve.ProductionMap as a JSON-RPC 2.0
+// service: every Resolver whose output is a named struct becomes a method
+// named "<PkgName>.<TypeName>", taking its inputs as named params, plus a
+// "resolve.chain" meta-method that drives a full ProductionMap.Resolve chain
+// from a bag of known inputs to any registered named struct type.
+package jsonrpc
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"reflect"
+	"strings"
+
+	"github.com/wyc/goresolve"
+)
+
+// rpcError is both the JSON-RPC 2.0 error object and a Go error, so method
+// implementations can return it directly.
+type rpcError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+func (e *rpcError) Error() string { return e.Message }
+
+func invalidParams(format string, args ...interface{}) *rpcError {
+	return &rpcError{Code: -32602, Message: "invalid params: " + fmt.Sprintf(format, args...)}
+}
+
+type rpcRequest struct {
+	JSONRPC string          `json:"jsonrpc"`
+	Method  string          `json:"method"`
+	Params  json.RawMessage `json:"params"`
+	ID      json.RawMessage `json:"id"`
+}
+
+type rpcResponse struct {
+	JSONRPC string          `json:"jsonrpc"`
+	Result  interface{}     `json:"result,omitempty"`
+	Error   *rpcError       `json:"error,omitempty"`
+	ID      json.RawMessage `json:"id,omitempty"`
+}
+
+// methodFunc implements one published RPC method: decode raw params, run the
+// Resolver (or resolve.chain), and return its result or an error.
+type methodFunc func(raw json.RawMessage) (interface{}, error)
+
+// Server is an http.Handler publishing pm's Resolvers as JSON-RPC 2.0
+// methods.
+type Server struct {
+	pm      resolve.ProductionMap
+	methods map[string]methodFunc
+	types   map[string]reflect.Type // type name -> type, for resolve.chain
+}
+
+// NewRPCServer builds a Server for pm: every Resolver whose output type is a
+// named struct gets a "<PkgName>.<TypeName>" method, and "resolve.chain" is
+// always available to derive any registered named struct type from a bag of
+// inputs; types that are not named structs are unknown to resolve.chain.
+func NewRPCServer(pm resolve.ProductionMap) http.Handler {
+	s := &Server{
+		pm:      pm,
+		methods: map[string]methodFunc{},
+		types:   map[string]reflect.Type{},
+	}
+
+	for _, r := range pm.List() {
+		r := *r
+		if base := namedStruct(r.OutputType()); base != nil {
+			s.types[base.Name()] = r.OutputType()
+		}
+		for _, in := range r.InputTypes() {
+			if base := namedStruct(in); base != nil {
+				s.types[base.Name()] = in
+			}
+		}
+
+		if namedStruct(r.OutputType()) == nil {
+			continue
+		}
+		s.methods[methodName(r.OutputType())] = s.directMethod(r)
+	}
+
+	s.methods["resolve.chain"] = s.chainMethod()
+	return s
+}
+
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	trimmed := bytes.TrimSpace(body)
+
+	w.Header().Set("Content-Type", "application/json")
+
+	if len(trimmed) > 0 && trimmed[0] == '[' {
+		var reqs []rpcRequest
+		if err := json.Unmarshal(trimmed, &reqs); err != nil {
+			json.NewEncoder(w).Encode(parseErrorResponse())
+			return
+		}
+		responses := make([]rpcResponse, len(reqs))
+		for i, req := range reqs {
+			responses[i] = s.handle(req)
+		}
+		json.NewEncoder(w).Encode(responses)
+		return
+	}
+
+	var req rpcRequest
+	if err := json.Unmarshal(trimmed, &req); err != nil {
+		json.NewEncoder(w).Encode(parseErrorResponse())
+		return
+	}
+	json.NewEncoder(w).Encode(s.handle(req))
+}
+
+func parseErrorResponse() rpcResponse {
+	return rpcResponse{JSONRPC: "2.0", Error: &rpcError{Code: -32700, Message: "parse error"}}
+}
+
+func (s *Server) handle(req rpcRequest) rpcResponse {
+	resp := rpcResponse{JSONRPC: "2.0", ID: req.ID}
+
+	method, ok := s.methods[req.Method]
+	if !ok {
+		resp.Error = &rpcError{Code: -32601, Message: "method not found: " + req.Method}
+		return resp
+	}
+
+	result, err := method(req.Params)
+	if err != nil {
+		if rpcErr, ok := err.(*rpcError); ok {
+			resp.Error = rpcErr
+		} else {
+			resp.Error = &rpcError{Code: -32603, Message: err.Error()}
+		}
+		return resp
+	}
+
+	resp.Result = result
+	return resp
+}
+
+// directMethod decodes params as a JSON object keyed by each input's
+// type-derived name, unmarshals each into a reflect.Value of the right type,
+// and dispatches via r.Resolve.
+func (s *Server) directMethod(r resolve.Resolver) methodFunc {
+	inputTypes := r.InputTypes()
+	return func(raw json.RawMessage) (interface{}, error) {
+		obj := map[string]json.RawMessage{}
+		if len(raw) > 0 {
+			if err := json.Unmarshal(raw, &obj); err != nil {
+				return nil, invalidParams("%s", err)
+			}
+		}
+
+		args := make([]reflect.Value, len(inputTypes))
+		for i, in := range inputTypes {
+			key := paramName(in)
+			paramRaw, ok := obj[key]
+			if !ok {
+				return nil, invalidParams("missing param %q", key)
+			}
+			v := reflect.New(in)
+			if err := json.Unmarshal(paramRaw, v.Interface()); err != nil {
+				return nil, invalidParams("param %q: %s", key, err)
+			}
+			args[i] = v.Elem()
+		}
+
+		out, err := r.Resolve(args...)
+		if err != nil {
+			return nil, &rpcError{Code: -32000, Message: err.Error()}
+		}
+		return out.Interface(), nil
+	}
+}
+
+// chainParams is the shape of resolve.chain's params: the name of the
+// target type, and a bag of already-known inputs keyed by type name.
+type chainParams struct {
+	Type   string                     `json:"type"`
+	Inputs map[string]json.RawMessage `json:"inputs"`
+}
+
+// chainMethod drives s.pm.Resolve end to end, so a client can ask for a
+// deeply-derived type (e.g. Axe, given Boulder and Tree) in one round trip.
+func (s *Server) chainMethod() methodFunc {
+	return func(raw json.RawMessage) (interface{}, error) {
+		var params chainParams
+		if err := json.Unmarshal(raw, &params); err != nil {
+			return nil, invalidParams("%s", err)
+		}
+
+		wanted, ok := s.types[params.Type]
+		if !ok {
+			return nil, invalidParams("unknown type %q", params.Type)
+		}
+
+		values := make([]reflect.Value, 0, len(params.Inputs))
+		for name, inputRaw := range params.Inputs {
+			t, ok := s.types[name]
+			if !ok {
+				return nil, invalidParams("unknown input type %q", name)
+			}
+			v := reflect.New(t)
+			if err := json.Unmarshal(inputRaw, v.Interface()); err != nil {
+				return nil, invalidParams("input %q: %s", name, err)
+			}
+			values = append(values, v.Elem())
+		}
+
+		out, err := s.pm.Resolve(wanted, values...)
+		if err != nil {
+			return nil, &rpcError{Code: -32000, Message: err.Error()}
+		}
+		return out.Interface(), nil
+	}
+}
+
+// namedStruct returns t (looking through any pointers) if it is a named
+// struct type, and nil otherwise.
+func namedStruct(t reflect.Type) reflect.Type {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t.Kind() == reflect.Struct && t.Name() != "" {
+		return t
+	}
+	return nil
+}
+
+// methodName builds the "<PkgName>.<TypeName>" RPC method name for t.
+func methodName(t reflect.Type) string {
+	base := namedStruct(t)
+	pkgPath := base.PkgPath()
+	pkgName := pkgPath
+	if idx := strings.LastIndex(pkgPath, "/"); idx >= 0 {
+		pkgName = pkgPath[idx+1:]
+	}
+	return fmt.Sprintf("%s.%s", pkgName, base.Name())
+}
+
+// paramName derives the JSON param key for an input type: for a named
+// struct, its type name with the first letter lower-cased; for any other
+// type, t.String() lower-cased in full, package qualifier included.
+func paramName(t reflect.Type) string {
+	base := namedStruct(t)
+	if base == nil {
+		return strings.ToLower(t.String())
+	}
+	return lowerFirst(base.Name())
+}
+
+func lowerFirst(s string) string {
+	if s == "" {
+		return s
+	}
+	return strings.ToLower(s[:1]) + s[1:]
+}
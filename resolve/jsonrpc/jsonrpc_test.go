@@ -0,0 +1,165 @@
+package jsonrpc
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/wyc/goresolve"
+)
+
+type Boulder struct{ Weight int }
+type Tree struct{ Height int }
+type Stick struct{ Height int }
+type Stone struct{ Weight int }
+type Axe struct {
+	Weight int
+	Height int
+}
+
+func buildAxeProductions(t *testing.T) resolve.ProductionMap {
+	productions := resolve.ProductionMap{}
+
+	chiselStone := func(b Boulder) (Stone, error) { return Stone{Weight: b.Weight / 10}, nil }
+	pickupStick := func(tr Tree) (Stick, error) { return Stick{Height: tr.Height / 10}, nil }
+	assembleAxe := func(stick Stick, stone Stone) (Axe, error) {
+		return Axe{Height: stick.Height, Weight: stone.Weight}, nil
+	}
+
+	for _, r := range []interface{}{chiselStone, pickupStick, assembleAxe} {
+		if err := productions.Add(resolve.Resolver{ResolverFunc: r}); err != nil {
+			t.Fatal("Failed to add Resolver:", err)
+		}
+	}
+	return productions
+}
+
+func post(t *testing.T, handler http.Handler, body interface{}) *httptest.ResponseRecorder {
+	t.Helper()
+	raw, err := json.Marshal(body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	req := httptest.NewRequest(http.MethodPost, "/rpc", bytes.NewReader(raw))
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, req)
+	return w
+}
+
+func TestDirectMethodDispatch(t *testing.T) {
+	handler := NewRPCServer(buildAxeProductions(t))
+
+	w := post(t, handler, map[string]interface{}{
+		"jsonrpc": "2.0",
+		"method":  "jsonrpc.Stone",
+		"params":  map[string]interface{}{"boulder": Boulder{Weight: 100}},
+		"id":      1,
+	})
+
+	var resp rpcResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatal(err)
+	}
+	if resp.Error != nil {
+		t.Fatalf("Unexpected RPC error: %+v", resp.Error)
+	}
+	result, ok := resp.Result.(map[string]interface{})
+	if !ok || result["Weight"] != float64(10) {
+		t.Errorf("Expected Stone{Weight: 10}, got %+v", resp.Result)
+	}
+}
+
+func TestResolveChainMethod(t *testing.T) {
+	handler := NewRPCServer(buildAxeProductions(t))
+
+	w := post(t, handler, map[string]interface{}{
+		"jsonrpc": "2.0",
+		"method":  "resolve.chain",
+		"params": map[string]interface{}{
+			"type": "Axe",
+			"inputs": map[string]interface{}{
+				"Boulder": Boulder{Weight: 100},
+				"Tree":    Tree{Height: 50},
+			},
+		},
+		"id": 2,
+	})
+
+	var resp rpcResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatal(err)
+	}
+	if resp.Error != nil {
+		t.Fatalf("Unexpected RPC error: %+v", resp.Error)
+	}
+	result, ok := resp.Result.(map[string]interface{})
+	if !ok || result["Weight"] != float64(10) || result["Height"] != float64(5) {
+		t.Errorf("Expected Axe{Weight: 10, Height: 5}, got %+v", resp.Result)
+	}
+}
+
+type Grip struct{ Material string }
+type Handle struct {
+	Length int
+	Grip   Grip
+}
+
+func TestDirectMethodDispatchWithNestedStruct(t *testing.T) {
+	productions := buildAxeProductions(t)
+
+	carveHandle := func(a Axe) (Handle, error) {
+		return Handle{Length: a.Height, Grip: Grip{Material: "oak"}}, nil
+	}
+	if err := productions.Add(resolve.Resolver{ResolverFunc: carveHandle}); err != nil {
+		t.Fatal("Failed to add Resolver:", err)
+	}
+	handler := NewRPCServer(productions)
+
+	w := post(t, handler, map[string]interface{}{
+		"jsonrpc": "2.0",
+		"method":  "jsonrpc.Handle",
+		"params":  map[string]interface{}{"axe": Axe{Weight: 10, Height: 5}},
+		"id":      1,
+	})
+
+	var resp rpcResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatal(err)
+	}
+	if resp.Error != nil {
+		t.Fatalf("Unexpected RPC error: %+v", resp.Error)
+	}
+	result, ok := resp.Result.(map[string]interface{})
+	if !ok {
+		t.Fatalf("Expected a result object, got %+v", resp.Result)
+	}
+	grip, ok := result["Grip"].(map[string]interface{})
+	if !ok || grip["Material"] != "oak" {
+		t.Errorf("Expected nested Grip{Material: \"oak\"} to round-trip through JSON, got %+v", result)
+	}
+}
+
+func TestBatchRequest(t *testing.T) {
+	handler := NewRPCServer(buildAxeProductions(t))
+
+	w := post(t, handler, []map[string]interface{}{
+		{"jsonrpc": "2.0", "method": "jsonrpc.Stone", "params": map[string]interface{}{"boulder": Boulder{Weight: 100}}, "id": 1},
+		{"jsonrpc": "2.0", "method": "no.such.method", "id": 2},
+	})
+
+	var responses []rpcResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &responses); err != nil {
+		t.Fatal(err)
+	}
+	if len(responses) != 2 {
+		t.Fatalf("Expected 2 responses, got %d", len(responses))
+	}
+	if responses[0].Error != nil {
+		t.Errorf("Expected first call to succeed, got error %+v", responses[0].Error)
+	}
+	if responses[1].Error == nil || responses[1].Error.Code != -32601 {
+		t.Errorf("Expected method-not-found error for second call, got %+v", responses[1].Error)
+	}
+}
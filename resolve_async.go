@@ -0,0 +1,249 @@
+package resolve
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sort"
+	"sync"
+)
+
+var contextType = reflect.TypeOf((*context.Context)(nil)).Elem()
+
+// TakesContext reports whether r's first input is a context.Context, the
+// same structural check Validate would need to make to know that ctx should
+// be threaded in automatically rather than resolved as an ordinary input.
+func (r Resolver) TakesContext() bool {
+	inputTypes := r.InputTypes()
+	return len(inputTypes) > 0 && inputTypes[0] == contextType
+}
+
+// Output is a promise for a value that a ResolveAsync chain is still
+// computing. It is resolved exactly once, after which Await always returns
+// immediately.
+type Output struct {
+	mu       sync.Mutex
+	cond     *sync.Cond
+	value    reflect.Value
+	err      error
+	resolved bool
+	deps     []*Output
+}
+
+func newOutput() *Output {
+	o := &Output{}
+	o.cond = sync.NewCond(&o.mu)
+	return o
+}
+
+func immediateOutput(v reflect.Value) *Output {
+	o := newOutput()
+	o.value = v
+	o.resolved = true
+	return o
+}
+
+func failedOutput(err error) *Output {
+	o := newOutput()
+	o.err = err
+	o.resolved = true
+	return o
+}
+
+func (o *Output) resolve(v reflect.Value, err error) {
+	o.mu.Lock()
+	o.value = v
+	o.err = err
+	o.resolved = true
+	o.cond.Broadcast()
+	o.mu.Unlock()
+}
+
+// Await blocks until o is resolved or ctx is done, whichever comes first.
+func (o *Output) Await(ctx context.Context) (reflect.Value, error) {
+	if err := ctx.Err(); err != nil {
+		return reflect.Value{}, err
+	}
+
+	// sync.Cond has no way to wait on a context, so wake any Wait()er if ctx
+	// is cancelled out from under it.
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		select {
+		case <-ctx.Done():
+			o.mu.Lock()
+			o.cond.Broadcast()
+			o.mu.Unlock()
+		case <-done:
+		}
+	}()
+
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	for !o.resolved && ctx.Err() == nil {
+		o.cond.Wait()
+	}
+	if !o.resolved {
+		return reflect.Value{}, ctx.Err()
+	}
+	return o.value, o.err
+}
+
+// Apply derives a new Output by running f on o's value once it resolves. If
+// f itself returns an *Output, it is transparently unwrapped, matching how
+// the engine unwraps output-of-output results from resolvers.
+func (o *Output) Apply(f func(reflect.Value) (reflect.Value, error)) *Output {
+	out := newOutput()
+	out.deps = []*Output{o}
+
+	go func() {
+		v, err := o.Await(context.Background())
+		if err != nil {
+			out.resolve(reflect.Value{}, err)
+			return
+		}
+		v, err = f(v)
+		if err != nil {
+			out.resolve(reflect.Value{}, err)
+			return
+		}
+		v, err = unwrapOutput(context.Background(), v)
+		out.resolve(v, err)
+	}()
+
+	return out
+}
+
+var outputPtrType = reflect.TypeOf((*Output)(nil))
+
+// unwrapOutput follows a chain of *Output values down to the concrete value
+// they ultimately resolve to.
+func unwrapOutput(ctx context.Context, v reflect.Value) (reflect.Value, error) {
+	for v.IsValid() && v.Type() == outputPtrType {
+		inner, _ := v.Interface().(*Output)
+		if inner == nil {
+			return reflect.Value{}, fmt.Errorf("resolver returned a nil *Output")
+		}
+		var err error
+		v, err = inner.Await(ctx)
+		if err != nil {
+			return reflect.Value{}, err
+		}
+	}
+	return v, nil
+}
+
+// asyncResolver builds one Output per resolved type, launching a goroutine
+// per resolver node and memoizing the Output for each type so that a value
+// needed by more than one branch is only ever computed once.
+type asyncResolver struct {
+	pm   ProductionMap
+	ctx  context.Context
+	have []reflect.Type
+
+	mu   sync.Mutex
+	memo map[reflect.Type]*Output
+}
+
+func (a *asyncResolver) get(wanted reflect.Type) *Output {
+	a.mu.Lock()
+	if o, ok := a.memo[wanted]; ok {
+		a.mu.Unlock()
+		return o
+	}
+	o := newOutput()
+	a.memo[wanted] = o
+	a.mu.Unlock()
+
+	candidates := append([]Resolver{}, a.pm[wanted]...)
+	if len(candidates) == 0 {
+		o.resolve(reflect.Value{}, fmt.Errorf("no resolver produces %v", wanted))
+		return o
+	}
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return a.pm.candidateCost(candidates[i], a.have) < a.pm.candidateCost(candidates[j], a.have)
+	})
+
+	go a.run(o, candidates[0])
+	return o
+}
+
+func (a *asyncResolver) run(o *Output, r Resolver) {
+	inputTypes := r.InputTypes()
+	args := make([]reflect.Value, len(inputTypes))
+	deps := make([]*Output, 0, len(inputTypes))
+	depArgs := make([]int, 0, len(inputTypes))
+
+	for i, in := range inputTypes {
+		if i == 0 && in == contextType {
+			// reflect.ValueOf(a.ctx) would carry ctx's concrete dynamic type
+			// rather than the context.Context interface type the resolver
+			// declares, so build the Value explicitly as that interface.
+			ctxVal := reflect.New(contextType).Elem()
+			ctxVal.Set(reflect.ValueOf(a.ctx))
+			args[i] = ctxVal
+			continue
+		}
+		// Start every input's derivation before awaiting any of them, so
+		// that independent inputs are computed in parallel.
+		deps = append(deps, a.get(in))
+		depArgs = append(depArgs, i)
+	}
+
+	o.mu.Lock()
+	o.deps = deps
+	o.mu.Unlock()
+
+	for j, dep := range deps {
+		v, err := dep.Await(a.ctx)
+		if err != nil {
+			o.resolve(reflect.Value{}, err)
+			return
+		}
+		args[depArgs[j]] = v
+	}
+
+	out, err := r.Resolve(args...)
+	if err != nil {
+		o.resolve(reflect.Value{}, err)
+		return
+	}
+
+	v, err := unwrapOutput(a.ctx, *out)
+	o.resolve(v, err)
+}
+
+// ResolveAsync is the concurrent counterpart to Resolve: it returns
+// immediately with an *Output representing the as-yet-unfinished derivation
+// of wanted, launching one goroutine per Resolver needed along the way so
+// that independent branches of the resolve tree run in parallel. As with
+// Resolve, it fails fast -- synchronously, via a pre-resolved Output -- if
+// wanted cannot possibly be derived from inputs given the current
+// productions.
+func (pm ProductionMap) ResolveAsync(ctx context.Context, wanted reflect.Type, inputs ...reflect.Value) *Output {
+	a := &asyncResolver{pm: pm, ctx: ctx, memo: map[reflect.Type]*Output{}}
+
+	have := make([]reflect.Type, len(inputs), len(inputs)+1)
+	for i, v := range inputs {
+		have[i] = v.Type()
+		a.memo[v.Type()] = immediateOutput(v)
+	}
+	// context.Context is threaded in automatically by run(), not supplied by
+	// the caller, so treat it as always on hand for pruning/cost purposes --
+	// otherwise any resolver with a leading ctx parameter looks unreachable
+	// to PruneFor even though a.get/a.run resolve it just fine.
+	have = append(have, contextType)
+	a.have = have
+
+	if o, ok := a.memo[wanted]; ok {
+		return o
+	}
+
+	root := pm.PossibilityTree(have...)
+	if err := root.PruneFor(wanted); err != nil {
+		return failedOutput(fmt.Errorf("%v cannot be derived from the given inputs: %s", wanted, err))
+	}
+
+	return a.get(wanted)
+}
@@ -0,0 +1,164 @@
+package resolve
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestResolveAsyncChain(t *testing.T) {
+	productions := ProductionMap{}
+
+	chiselStone := func(b Boulder) (Stone, error) { return Stone{Weight: b.Weight / 10}, nil }
+	pickupStick := func(t Tree) (Stick, error) { return Stick{Height: t.Height / 10}, nil }
+	assembleAxe := func(stick Stick, stone Stone) (Axe, error) {
+		return Axe{Height: stick.Height, Weight: stone.Weight}, nil
+	}
+
+	for _, r := range []interface{}{chiselStone, pickupStick, assembleAxe} {
+		if err := productions.Add(Resolver{r}); err != nil {
+			t.Fatal("Failed to add Resolver:", err)
+		}
+	}
+
+	out := productions.ResolveAsync(context.Background(), reflect.TypeOf(Axe{}),
+		reflect.ValueOf(Boulder{Weight: 100}),
+		reflect.ValueOf(Tree{Height: 50}),
+	)
+	v, err := out.Await(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	axe, ok := v.Interface().(Axe)
+	if !ok {
+		t.Fatal("Returned type was not Axe")
+	}
+	if axe.Weight != 10 || axe.Height != 5 {
+		t.Errorf("Expected Axe{Weight: 10, Height: 5}, got %+v", axe)
+	}
+}
+
+func TestResolveAsyncUnreachableFailsFast(t *testing.T) {
+	productions := ProductionMap{}
+
+	chiselStone := func(b Boulder) (Stone, error) { return Stone{Weight: b.Weight / 10}, nil }
+	if err := productions.Add(Resolver{chiselStone}); err != nil {
+		t.Fatal("Failed to add Resolver:", err)
+	}
+
+	out := productions.ResolveAsync(context.Background(), reflect.TypeOf(Axe{}), reflect.ValueOf(Boulder{Weight: 100}))
+	if _, err := out.Await(context.Background()); err == nil {
+		t.Fatal("Expected error resolving an unreachable type, got nil")
+	}
+}
+
+func TestResolveAsyncContextCancellation(t *testing.T) {
+	productions := ProductionMap{}
+
+	block := make(chan struct{})
+	slowResolver := func(b Boulder) (Stone, error) {
+		<-block
+		return Stone{Weight: b.Weight / 10}, nil
+	}
+	if err := productions.Add(Resolver{slowResolver}); err != nil {
+		t.Fatal("Failed to add Resolver:", err)
+	}
+	defer close(block)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	out := productions.ResolveAsync(ctx, reflect.TypeOf(Stone{}), reflect.ValueOf(Boulder{Weight: 100}))
+
+	cancel()
+
+	done := make(chan struct{})
+	var err error
+	go func() {
+		_, err = out.Await(ctx)
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("Await did not wake up after context cancellation")
+	}
+	if err != context.Canceled {
+		t.Errorf("Expected context.Canceled, got %v", err)
+	}
+}
+
+func TestOutputApply(t *testing.T) {
+	productions := ProductionMap{}
+
+	chiselStone := func(b Boulder) (Stone, error) { return Stone{Weight: b.Weight / 10}, nil }
+	if err := productions.Add(Resolver{chiselStone}); err != nil {
+		t.Fatal("Failed to add Resolver:", err)
+	}
+
+	stoneOutput := productions.ResolveAsync(context.Background(), reflect.TypeOf(Stone{}), reflect.ValueOf(Boulder{Weight: 100}))
+	doubled := stoneOutput.Apply(func(v reflect.Value) (reflect.Value, error) {
+		s := v.Interface().(Stone)
+		return reflect.ValueOf(Stone{Weight: s.Weight * 2}), nil
+	})
+
+	v, err := doubled.Await(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s := v.Interface().(Stone); s.Weight != 20 {
+		t.Errorf("Expected Stone{Weight: 20}, got %+v", s)
+	}
+}
+
+func TestResolveAsyncContextResolver(t *testing.T) {
+	productions := ProductionMap{}
+
+	chiselStone := func(ctx context.Context, b Boulder) (Stone, error) {
+		if err := ctx.Err(); err != nil {
+			return Stone{}, err
+		}
+		return Stone{Weight: b.Weight / 10}, nil
+	}
+	if err := productions.Add(Resolver{chiselStone}); err != nil {
+		t.Fatal("Failed to add Resolver:", err)
+	}
+
+	resolvers := productions[reflect.TypeOf(Stone{})]
+	if !resolvers[0].TakesContext() {
+		t.Fatal("Expected resolver to be detected as taking a context.Context")
+	}
+
+	out := productions.ResolveAsync(context.Background(), reflect.TypeOf(Stone{}), reflect.ValueOf(Boulder{Weight: 100}))
+	v, err := out.Await(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s := v.Interface().(Stone); s.Weight != 10 {
+		t.Errorf("Expected Stone{Weight: 10}, got %+v", s)
+	}
+}
+
+func TestUnwrapOutputOfOutput(t *testing.T) {
+	productions := ProductionMap{}
+
+	innerStoneOutput := func(b Boulder) (*Output, error) {
+		return immediateOutput(reflect.ValueOf(Stone{Weight: b.Weight / 10})), nil
+	}
+	if err := productions.Add(Resolver{innerStoneOutput}); err != nil {
+		t.Fatal("Failed to add Resolver:", err)
+	}
+
+	out := productions.ResolveAsync(context.Background(), reflect.TypeOf((*Output)(nil)), reflect.ValueOf(Boulder{Weight: 100}))
+	v, err := out.Await(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	stone, ok := v.Interface().(Stone)
+	if !ok {
+		t.Fatalf("Expected unwrapped Stone value, got %v", v.Interface())
+	}
+	if stone.Weight != 10 {
+		t.Errorf("Expected Stone{Weight: 10}, got %+v", stone)
+	}
+}
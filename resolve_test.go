@@ -393,6 +393,153 @@ func TestPossibilityTreeBuildAxe(t *testing.T) {
 
 }
 
+func TestResolveChain(t *testing.T) {
+	productions := ProductionMap{}
+
+	chiselStone := func(b Boulder) (Stone, error) { return Stone{Weight: b.Weight / 10}, nil }
+	pickupStick := func(t Tree) (Stick, error) { return Stick{Height: t.Height / 10}, nil }
+	assembleAxe := func(stick Stick, stone Stone) (Axe, error) {
+		return Axe{Height: stick.Height, Weight: stone.Weight}, nil
+	}
+
+	for _, r := range []interface{}{chiselStone, pickupStick, assembleAxe} {
+		if err := productions.Add(Resolver{r}); err != nil {
+			t.Fatal("Failed to add Resolver:", err)
+		}
+	}
+
+	out, err := productions.Resolve(
+		reflect.TypeOf(Axe{}),
+		reflect.ValueOf(Boulder{Weight: 100}),
+		reflect.ValueOf(Tree{Height: 50}),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	axe, ok := out.Interface().(Axe)
+	if !ok {
+		t.Fatal("Returned type was not Axe")
+	}
+	if axe.Weight != 10 || axe.Height != 5 {
+		t.Errorf("Expected Axe{Weight: 10, Height: 5}, got %+v", axe)
+	}
+}
+
+func TestResolveAlreadyHaveWanted(t *testing.T) {
+	productions := ProductionMap{}
+
+	out, err := productions.Resolve(reflect.TypeOf(Boulder{}), reflect.ValueOf(Boulder{Weight: 42}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if b, ok := out.Interface().(Boulder); !ok || b.Weight != 42 {
+		t.Errorf("Expected Boulder{Weight: 42}, got %+v", out.Interface())
+	}
+}
+
+func TestResolveUnreachable(t *testing.T) {
+	productions := ProductionMap{}
+
+	chiselStone := func(b Boulder) (Stone, error) { return Stone{Weight: b.Weight / 10}, nil }
+	if err := productions.Add(Resolver{chiselStone}); err != nil {
+		t.Fatal("Failed to add Resolver:", err)
+	}
+
+	_, err := productions.Resolve(reflect.TypeOf(Axe{}), reflect.ValueOf(Boulder{Weight: 100}))
+	if err == nil {
+		t.Fatal("Expected error resolving an unreachable type, got nil")
+	}
+}
+
+func TestResolveAllChainsFail(t *testing.T) {
+	productions := ProductionMap{}
+
+	failingResolver := func(b Boulder) (Stone, error) {
+		return Stone{}, fmt.Errorf("chisel broke")
+	}
+	if err := productions.Add(Resolver{failingResolver}); err != nil {
+		t.Fatal("Failed to add Resolver:", err)
+	}
+
+	_, err := productions.Resolve(reflect.TypeOf(Stone{}), reflect.ValueOf(Boulder{Weight: 100}))
+	if err == nil {
+		t.Fatal("Expected error when every resolution chain fails, got nil")
+	}
+}
+
+func TestResolveShortestChainWins(t *testing.T) {
+	productions := ProductionMap{}
+
+	type RoughStone struct{ Weight int }
+
+	directChisel := func(b Boulder) (Stone, error) { return Stone{Weight: b.Weight}, nil }
+	quarryRough := func(b Boulder) (RoughStone, error) { return RoughStone{Weight: b.Weight}, nil }
+	chiselRough := func(r RoughStone) (Stone, error) { return Stone{Weight: r.Weight * 1000}, nil }
+
+	for _, r := range []interface{}{directChisel, quarryRough, chiselRough} {
+		if err := productions.Add(Resolver{r}); err != nil {
+			t.Fatal("Failed to add Resolver:", err)
+		}
+	}
+
+	out, err := productions.Resolve(reflect.TypeOf(Stone{}), reflect.ValueOf(Boulder{Weight: 10}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	stone, ok := out.Interface().(Stone)
+	if !ok {
+		t.Fatal("Returned type was not Stone")
+	}
+	if stone.Weight != 10 {
+		t.Errorf("Expected the depth-1 chisel chain to win with Stone{Weight: 10}, got %+v (the depth-2 chain would give 10000)", stone)
+	}
+}
+
+func TestResolveSharedDependencyResolvedOnce(t *testing.T) {
+	productions := ProductionMap{}
+
+	type StoneWeight int
+	type StoneDescription string
+	type Summary struct {
+		Weight      StoneWeight
+		Description StoneDescription
+	}
+
+	chiselCalls := 0
+	chiselStone := func(b Boulder) (Stone, error) {
+		chiselCalls++
+		return Stone{Weight: b.Weight / 10}, nil
+	}
+	weighStone := func(s Stone) (StoneWeight, error) { return StoneWeight(s.Weight), nil }
+	describeStone := func(s Stone) (StoneDescription, error) {
+		return StoneDescription(fmt.Sprintf("stone of weight %d", s.Weight)), nil
+	}
+	summarize := func(w StoneWeight, d StoneDescription) (Summary, error) {
+		return Summary{Weight: w, Description: d}, nil
+	}
+
+	for _, r := range []interface{}{chiselStone, weighStone, describeStone, summarize} {
+		if err := productions.Add(Resolver{r}); err != nil {
+			t.Fatal("Failed to add Resolver:", err)
+		}
+	}
+
+	out, err := productions.Resolve(reflect.TypeOf(Summary{}), reflect.ValueOf(Boulder{Weight: 100}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	summary, ok := out.Interface().(Summary)
+	if !ok {
+		t.Fatal("Returned type was not Summary")
+	}
+	if summary.Weight != 10 || summary.Description != "stone of weight 10" {
+		t.Errorf("Expected Summary{Weight: 10, Description: \"stone of weight 10\"}, got %+v", summary)
+	}
+	if chiselCalls != 1 {
+		t.Errorf("Expected chiselStone to run exactly once for a Stone shared by two branches, ran %d times", chiselCalls)
+	}
+}
+
 // AddIdentityResolverHTTP(User{}, RequestToUser)
 
 // AddRoleResolver(User.GetManager)